@@ -0,0 +1,35 @@
+package youtube
+
+import "errors"
+
+// ErrNoHlsManifest is returned by Client.GetHLSStream when the requested
+// format isn't part of an ongoing live broadcast and so carries no HLS
+// manifest URL.
+var ErrNoHlsManifest = errors.New("format has no HLS manifest URL")
+
+// Format describes one quality/itag variant of a video's streaming
+// formats, as returned by the innertube player endpoint's streamingData.
+type Format struct {
+	ItagNo        int    `json:"itag"`
+	URL           string `json:"url"`
+	MimeType      string `json:"mimeType"`
+	Quality       string `json:"quality"`
+	Cipher        string `json:"signatureCipher"`
+	Bitrate       int    `json:"bitrate"`
+	FPS           int    `json:"fps"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	ContentLength int64  `json:"contentLength,string"`
+	QualityLabel  string `json:"qualityLabel"`
+	AudioQuality  string `json:"audioQuality"`
+	AudioChannels int    `json:"audioChannels"`
+
+	// HlsManifestUrl is set on formats belonging to an ongoing live
+	// broadcast; it points at the HLS master playlist for the stream.
+	// Use Client.GetHLSStream to consume it.
+	HlsManifestUrl string `json:"hlsManifestUrl,omitempty"`
+}
+
+// FormatList is a list of Format, sortable by DebugString's callers via
+// SortByAudio/SortByVideo.
+type FormatList []Format
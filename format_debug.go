@@ -0,0 +1,132 @@
+package youtube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DebugString renders a single-line, human-readable summary of f for logs
+// and CLI format tables: itag, container, quality, bitrate and codec.
+// includeURL additionally appends the format's stream URL, which is long
+// and often sensitive (signed, time-limited), so callers should default
+// it to false unless they're specifically debugging URL/cipher issues.
+func (f *Format) DebugString(includeURL bool) string {
+	s := fmt.Sprintf("itag=%d mime=%q quality=%s bitrate=%d", f.ItagNo, f.MimeType, f.qualityLabel(), f.Bitrate)
+
+	if codec := codecsOf(f.MimeType); codec != "" {
+		s += " codec=" + codec
+	}
+	if f.AudioQuality != "" {
+		s += fmt.Sprintf(" audioQuality=%s audioChannels=%d", f.AudioQuality, f.AudioChannels)
+	}
+	if f.Width != 0 || f.Height != 0 {
+		s += fmt.Sprintf(" %dx%d fps=%d", f.Width, f.Height, f.FPS)
+	}
+
+	if includeURL {
+		s += " url=" + f.URL
+	}
+
+	return s
+}
+
+func (f *Format) qualityLabel() string {
+	if f.QualityLabel != "" {
+		return f.QualityLabel
+	}
+	return f.Quality
+}
+
+// codecsOf extracts the codecs parameter from a MIME type such as
+// `video/mp4; codecs="avc1.640028"`, returning "" if absent.
+func codecsOf(mimeType string) string {
+	const marker = `codecs="`
+
+	i := strings.Index(mimeType, marker)
+	if i < 0 {
+		return ""
+	}
+
+	rest := mimeType[i+len(marker):]
+	if j := strings.IndexByte(rest, '"'); j >= 0 {
+		return rest[:j]
+	}
+	return rest
+}
+
+// audioQualityRank maps innertube's AUDIO_QUALITY_* strings to an ordinal
+// so they sort by loudness/fidelity rather than alphabetically; unknown
+// values rank lowest.
+func audioQualityRank(quality string) int {
+	switch quality {
+	case "AUDIO_QUALITY_HIGH":
+		return 2
+	case "AUDIO_QUALITY_MEDIUM":
+		return 1
+	case "AUDIO_QUALITY_LOW":
+		return 0
+	default:
+		return -1
+	}
+}
+
+// SortByAudio sorts the list in descending order of preference for audio
+// playback: bitrate, then quality, then codec.
+func (list FormatList) SortByAudio() {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Bitrate != b.Bitrate {
+			return a.Bitrate > b.Bitrate
+		}
+		if aq, bq := audioQualityRank(a.AudioQuality), audioQualityRank(b.AudioQuality); aq != bq {
+			return aq > bq
+		}
+		return codecsOf(a.MimeType) > codecsOf(b.MimeType)
+	})
+}
+
+// videoQualityRanks maps innertube's Quality tokens to an approximate
+// vertical resolution, so they sort by actual resolution rather than
+// alphabetically (e.g. "tiny" > "hd1080" as strings, which is backwards).
+var videoQualityRanks = map[string]int{
+	"tiny":    144,
+	"small":   240,
+	"medium":  360,
+	"large":   480,
+	"hd720":   720,
+	"hd1080":  1080,
+	"hd1440":  1440,
+	"hd2160":  2160,
+	"hd2880":  2880,
+	"highres": 4320,
+}
+
+// videoQualityRank ranks f by resolution: Height when the format reports
+// one, falling back to Quality's approximate resolution for formats (such
+// as some DASH audio-only entries matched against video formats) that
+// don't. Unknown Quality tokens rank lowest.
+func videoQualityRank(f Format) int {
+	if f.Height > 0 {
+		return f.Height
+	}
+	if rank, ok := videoQualityRanks[f.Quality]; ok {
+		return rank
+	}
+	return -1
+}
+
+// SortByVideo sorts the list in descending order of preference for video
+// playback: bitrate, then quality, then codec.
+func (list FormatList) SortByVideo() {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Bitrate != b.Bitrate {
+			return a.Bitrate > b.Bitrate
+		}
+		if aq, bq := videoQualityRank(a), videoQualityRank(b); aq != bq {
+			return aq > bq
+		}
+		return codecsOf(a.MimeType) > codecsOf(b.MimeType)
+	})
+}
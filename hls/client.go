@@ -0,0 +1,359 @@
+// Package hls streams YouTube live broadcasts exposed as HLS, following
+// the HlsManifestUrl carried on a live video's Format entries.
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinRefreshInterval is the minimum pause between media playlist
+// refetches, so a stalled or slow-moving broadcast doesn't get hammered.
+const MinRefreshInterval = 5 * time.Second
+
+// Segment is one media segment referenced by a playlist.
+type Segment struct {
+	URI string
+
+	// Discontinuity is true if the segment follows an
+	// EXT-X-DISCONTINUITY tag, signalling a change in encoding
+	// parameters that recording tools may need to handle specially.
+	Discontinuity bool
+
+	// ProgramDateTime is the wall-clock time of the segment's first
+	// sample, taken from the most recent EXT-X-PROGRAM-DATE-TIME tag.
+	// It is the zero time if the playlist doesn't carry one.
+	ProgramDateTime time.Time
+}
+
+// Event is reported to Client.OnEvent as each new segment is discovered.
+type Event struct {
+	Segment Segment
+}
+
+// Client streams segments from a YouTube live HLS media playlist.
+type Client struct {
+	// HTTPClient can be used to set a custom HTTP client.
+	// If not set, http.DefaultClient will be used.
+	HTTPClient *http.Client
+
+	// OnEvent, if set, is called for every new segment as it is
+	// discovered, before its body is downloaded, so callers can observe
+	// discontinuities and program-date-time without parsing manifests
+	// themselves.
+	OnEvent func(Event)
+}
+
+// Stream resolves manifestURL (HlsManifestUrl is a master playlist) down
+// to a media playlist, then polls it for new segments and pipes their
+// bodies, in playlist order, to the returned reader. It stops when the
+// playlist carries #EXT-X-ENDLIST or ctx is canceled.
+func (c *Client) Stream(ctx context.Context, manifestURL string) (io.ReadCloser, error) {
+	r, w := io.Pipe()
+
+	go func() {
+		//nolint:errcheck
+		w.CloseWithError(c.run(ctx, manifestURL, w))
+	}()
+
+	return r, nil
+}
+
+func (c *Client) run(ctx context.Context, manifestURL string, w io.Writer) error {
+	mediaURL, err := c.resolveMediaPlaylistURL(ctx, manifestURL)
+	if err != nil {
+		return err
+	}
+
+	base, err := url.Parse(mediaURL)
+	if err != nil {
+		return fmt.Errorf("hls: invalid media playlist URL %q: %w", mediaURL, err)
+	}
+
+	queue := newSegmentQueue()
+
+	for {
+		playlist, err := c.fetchPlaylist(ctx, mediaURL, base)
+		if err != nil {
+			return err
+		}
+
+		for _, seg := range playlist.segments {
+			if !queue.addIfNew(seg.URI) {
+				continue
+			}
+
+			if c.OnEvent != nil {
+				c.OnEvent(Event{Segment: seg})
+			}
+
+			if err := c.downloadSegment(ctx, seg.URI, w); err != nil {
+				return err
+			}
+		}
+
+		if playlist.endList {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(MinRefreshInterval):
+		}
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// fetchBody issues a GET to reqURL and returns its body once the response
+// is confirmed 200 OK. The caller must close it.
+func (c *Client) fetchBody(ctx context.Context, reqURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("hls: unexpected status code %v for %s", resp.StatusCode, reqURL)
+	}
+
+	return resp.Body, nil
+}
+
+func (c *Client) downloadSegment(ctx context.Context, uri string, w io.Writer) error {
+	body, err := c.fetchBody(ctx, uri)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// variant is one rendition offered by an HLS master playlist.
+type variant struct {
+	uri       string
+	bandwidth int
+}
+
+// resolveMediaPlaylistURL fetches manifestURL and, if it is an HLS master
+// playlist (#EXT-X-STREAM-INF variants, as HlsManifestUrl always is),
+// selects the highest-bandwidth variant and returns its media playlist
+// URL. If manifestURL is already a media playlist, it is returned
+// unchanged.
+func (c *Client) resolveMediaPlaylistURL(ctx context.Context, manifestURL string) (string, error) {
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return "", fmt.Errorf("hls: invalid manifest URL %q: %w", manifestURL, err)
+	}
+
+	body, err := c.fetchBody(ctx, manifestURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	if !bytes.Contains(data, []byte("#EXT-X-STREAM-INF:")) {
+		// already a media playlist
+		return manifestURL, nil
+	}
+
+	variants, err := parseMasterPlaylist(bytes.NewReader(data), base)
+	if err != nil {
+		return "", err
+	}
+	if len(variants) == 0 {
+		return "", fmt.Errorf("hls: master playlist at %s has no variants", manifestURL)
+	}
+
+	best := variants[0]
+	for _, v := range variants[1:] {
+		if v.bandwidth > best.bandwidth {
+			best = v
+		}
+	}
+	return best.uri, nil
+}
+
+// parseMasterPlaylist parses an HLS master playlist's #EXT-X-STREAM-INF
+// variants, resolving each variant URI against base.
+func parseMasterPlaylist(r io.Reader, base *url.URL) ([]variant, error) {
+	var variants []variant
+	var pendingBandwidth int
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			pendingBandwidth = parseBandwidth(line)
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			ref, err := url.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("hls: invalid variant URI %q: %w", line, err)
+			}
+			variants = append(variants, variant{
+				uri:       base.ResolveReference(ref).String(),
+				bandwidth: pendingBandwidth,
+			})
+			pendingBandwidth = 0
+		}
+	}
+
+	return variants, scanner.Err()
+}
+
+// parseBandwidth extracts the BANDWIDTH attribute from an
+// #EXT-X-STREAM-INF tag, returning 0 if absent or unparseable.
+func parseBandwidth(tag string) int {
+	attrs := strings.TrimPrefix(tag, "#EXT-X-STREAM-INF:")
+	for _, attr := range strings.Split(attrs, ",") {
+		kv := strings.SplitN(attr, "=", 2)
+		if len(kv) != 2 || kv[0] != "BANDWIDTH" {
+			continue
+		}
+		if n, err := strconv.Atoi(kv[1]); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// parseExtinfDuration extracts the duration, in seconds, from an
+// #EXTINF:<duration>,<title> tag, returning 0 if it's missing or
+// unparseable.
+func parseExtinfDuration(tag string) time.Duration {
+	rest := strings.TrimPrefix(tag, "#EXTINF:")
+	if i := strings.IndexByte(rest, ','); i >= 0 {
+		rest = rest[:i]
+	}
+
+	seconds, err := strconv.ParseFloat(rest, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// mediaPlaylist is the result of parsing one media playlist fetch.
+type mediaPlaylist struct {
+	segments []Segment
+	endList  bool
+}
+
+func (c *Client) fetchPlaylist(ctx context.Context, mediaURL string, base *url.URL) (*mediaPlaylist, error) {
+	body, err := c.fetchBody(ctx, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return parseMediaPlaylist(body, base)
+}
+
+// parseMediaPlaylist is a minimal EXT-M3U8 parser covering the tags we
+// need to track segments in order: EXTINF, EXT-X-DISCONTINUITY,
+// EXT-X-PROGRAM-DATE-TIME and EXT-X-ENDLIST. Segment URIs are resolved
+// against base, since playlists commonly reference segments relatively.
+// Each EXT-X-PROGRAM-DATE-TIME anchors the wall-clock time of the segment
+// that follows it; every later segment's ProgramDateTime is derived by
+// accumulating EXTINF durations from that anchor until a fresh
+// EXT-X-PROGRAM-DATE-TIME tag resets it.
+func parseMediaPlaylist(r io.Reader, base *url.URL) (*mediaPlaylist, error) {
+	p := &mediaPlaylist{}
+
+	var pendingDiscontinuity bool
+	var pendingPDT time.Time
+	var pendingDuration time.Duration
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+		case line == "#EXT-X-DISCONTINUITY":
+			pendingDiscontinuity = true
+		case strings.HasPrefix(line, "#EXTINF:"):
+			pendingDuration = parseExtinfDuration(line)
+		case strings.HasPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:"):
+			if t, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(line, "#EXT-X-PROGRAM-DATE-TIME:")); err == nil {
+				pendingPDT = t
+			}
+		case line == "#EXT-X-ENDLIST":
+			p.endList = true
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			ref, err := url.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("hls: invalid segment URI %q: %w", line, err)
+			}
+			p.segments = append(p.segments, Segment{
+				URI:             base.ResolveReference(ref).String(),
+				Discontinuity:   pendingDiscontinuity,
+				ProgramDateTime: pendingPDT,
+			})
+			pendingDiscontinuity = false
+
+			if !pendingPDT.IsZero() {
+				pendingPDT = pendingPDT.Add(pendingDuration)
+			}
+			pendingDuration = 0
+		}
+	}
+
+	return p, scanner.Err()
+}
+
+// segmentQueue deduplicates segment URIs already seen across playlist
+// refreshes.
+type segmentQueue struct {
+	seen map[string]struct{}
+}
+
+func newSegmentQueue() *segmentQueue {
+	return &segmentQueue{seen: make(map[string]struct{})}
+}
+
+// addIfNew reports whether uri hasn't been queued before, recording it if
+// so.
+func (q *segmentQueue) addIfNew(uri string) bool {
+	if _, ok := q.seen[uri]; ok {
+		return false
+	}
+	q.seen[uri] = struct{}{}
+	return true
+}
@@ -0,0 +1,199 @@
+// Package s3sink adapts an S3 multipart upload to an io.Writer, so a
+// stream such as youtube.Client.Download can be piped straight to object
+// storage without buffering the whole object to disk or memory.
+package s3sink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// MinPartSize is the smallest part S3 accepts for all but the final
+	// part of a multipart upload.
+	MinPartSize = 5 << 20 // 5 MiB
+
+	// DefaultPartSize is used when MultipartWriter isn't given one.
+	DefaultPartSize = 8 << 20 // 8 MiB
+
+	// MaxPartSize is the largest part size MultipartWriter will buffer.
+	MaxPartSize = 16 << 20 // 16 MiB
+)
+
+// S3Client is the subset of the AWS SDK's S3 client that MultipartWriter
+// depends on. *s3.Client satisfies it directly.
+type S3Client interface {
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// MultipartWriter is an io.WriteCloser that streams writes into an S3
+// multipart upload, buffering partSize bytes at a time internally and
+// uploading each part as it fills. Close flushes the remainder as the
+// final part and completes the upload; ctx cancellation aborts it.
+type MultipartWriter struct {
+	ctx      context.Context
+	client   S3Client
+	bucket   string
+	key      string
+	partSize int
+
+	uploadID  string
+	buf       bytes.Buffer
+	partNum   int32
+	completed []types.CompletedPart
+
+	aborted bool
+	etag    string
+	objKey  string
+}
+
+// NewMultipartWriter starts a multipart upload to bucket/key and returns a
+// writer that streams into it. partSize is clamped to
+// [MinPartSize, MaxPartSize], defaulting to DefaultPartSize when zero.
+func NewMultipartWriter(ctx context.Context, client S3Client, bucket, key string, partSize int) (*MultipartWriter, error) {
+	switch {
+	case partSize <= 0:
+		partSize = DefaultPartSize
+	case partSize < MinPartSize:
+		partSize = MinPartSize
+	case partSize > MaxPartSize:
+		partSize = MaxPartSize
+	}
+
+	out, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3sink: create multipart upload: %w", err)
+	}
+
+	return &MultipartWriter{
+		ctx:      ctx,
+		client:   client,
+		bucket:   bucket,
+		key:      key,
+		partSize: partSize,
+		uploadID: aws.ToString(out.UploadId),
+	}, nil
+}
+
+// Write buffers p, uploading full parts to S3 as they fill. A failed part
+// upload, or ctx being canceled, aborts the multipart upload; the returned
+// count covers only the prefix of p actually buffered before the failure,
+// so callers retrying don't double-count the rest.
+func (w *MultipartWriter) Write(p []byte) (int, error) {
+	var written int
+
+	for len(p) > 0 {
+		chunk := p
+		if free := w.partSize - w.buf.Len(); len(chunk) > free {
+			chunk = chunk[:free]
+		}
+
+		n, _ := w.buf.Write(chunk) // bytes.Buffer.Write never errors
+		written += n
+		p = p[n:]
+
+		if w.buf.Len() >= w.partSize {
+			if err := w.flushPart(w.buf.Next(w.partSize)); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// Close flushes any buffered remainder as the final part and completes
+// the upload. On success, ETag and Key return the completed object's
+// identifiers.
+func (w *MultipartWriter) Close() error {
+	if w.aborted {
+		return errors.New("s3sink: upload was aborted")
+	}
+
+	if w.buf.Len() > 0 {
+		data := make([]byte, w.buf.Len())
+		copy(data, w.buf.Bytes())
+		if err := w.flushPart(data); err != nil {
+			return err
+		}
+		w.buf.Reset()
+	}
+
+	out, err := w.client.CompleteMultipartUpload(w.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(w.bucket),
+		Key:      aws.String(w.key),
+		UploadId: aws.String(w.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: w.completed,
+		},
+	})
+	if err != nil {
+		//nolint:errcheck
+		w.abort()
+		return fmt.Errorf("s3sink: complete multipart upload: %w", err)
+	}
+
+	w.etag = aws.ToString(out.ETag)
+	w.objKey = aws.ToString(out.Key)
+	return nil
+}
+
+// ETag returns the completed object's ETag. Only valid after a successful
+// Close.
+func (w *MultipartWriter) ETag() string { return w.etag }
+
+// Key returns the completed object's key. Only valid after a successful
+// Close.
+func (w *MultipartWriter) Key() string { return w.objKey }
+
+func (w *MultipartWriter) flushPart(data []byte) error {
+	select {
+	case <-w.ctx.Done():
+		//nolint:errcheck
+		w.abort()
+		return w.ctx.Err()
+	default:
+	}
+
+	w.partNum++
+	out, err := w.client.UploadPart(w.ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(w.bucket),
+		Key:        aws.String(w.key),
+		UploadId:   aws.String(w.uploadID),
+		PartNumber: aws.Int32(w.partNum),
+		Body:       bytes.NewReader(data),
+	})
+	if err != nil {
+		//nolint:errcheck
+		w.abort()
+		return fmt.Errorf("s3sink: upload part %d: %w", w.partNum, err)
+	}
+
+	w.completed = append(w.completed, types.CompletedPart{
+		ETag:       out.ETag,
+		PartNumber: aws.Int32(w.partNum),
+	})
+	return nil
+}
+
+func (w *MultipartWriter) abort() error {
+	w.aborted = true
+	_, err := w.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(w.bucket),
+		Key:      aws.String(w.key),
+		UploadId: aws.String(w.uploadID),
+	})
+	return err
+}